@@ -0,0 +1,25 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestOrganizationID_Stable(t *testing.T) {
+	if OrganizationID("acme") != OrganizationID("acme") {
+		t.Fatal("expected OrganizationID to be deterministic for the same name")
+	}
+	if OrganizationID("acme") == OrganizationID("beta") {
+		t.Fatal("expected different organization names to produce different ids")
+	}
+}
+
+func TestProvider_VerifyMembership_UnknownID(t *testing.T) {
+	nameByID := func(id int64) (string, error) { return "", fmt.Errorf("no configured organization with id %d", id) }
+	p := New(nil, nameByID)
+
+	if _, err := p.VerifyMembership(context.Background(), "tok", 999); err == nil {
+		t.Fatal("expected an error resolving an unknown organization id")
+	}
+}
@@ -0,0 +1,122 @@
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc, nameByID func(int64) (string, error)) *Provider {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	base, err := url.Parse(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return New(base, ts.Client(), nameByID)
+}
+
+func TestProvider_ResolveOrgID(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workspaces/acme" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"slug": "acme"}`))
+	}, nil)
+
+	id, err := p.ResolveOrgID(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := WorkspaceID("acme"); id != want {
+		t.Fatalf("expected id %d, got %d", want, id)
+	}
+}
+
+func TestProvider_VerifyMembership_Member(t *testing.T) {
+	nameByID := func(id int64) (string, error) {
+		if id != WorkspaceID("acme") {
+			return "", fmt.Errorf("unexpected id %d", id)
+		}
+		return "acme", nil
+	}
+
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			w.Write([]byte(`{"uuid": "{user-uuid}"}`))
+		case "/workspaces/acme/members/{user-uuid}":
+			w.Write([]byte(`{"user": {"uuid": "{user-uuid}"}}`))
+		case "/workspaces/acme/projects":
+			w.Write([]byte(`{"values": [{"name": "payments"}]}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}, nameByID)
+
+	projects, err := p.VerifyMembership(context.Background(), "tok", WorkspaceID("acme"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 1 || projects[0] != "payments" {
+		t.Fatalf("unexpected projects %v", projects)
+	}
+}
+
+func TestProvider_VerifyMembership_NotMember(t *testing.T) {
+	nameByID := func(id int64) (string, error) { return "acme", nil }
+
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			w.Write([]byte(`{"uuid": "{user-uuid}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}, nameByID)
+
+	_, err := p.VerifyMembership(context.Background(), "tok", WorkspaceID("acme"))
+	if !errors.Is(err, scmauth.ErrNotMember) {
+		t.Fatalf("expected scmauth.ErrNotMember, got %v", err)
+	}
+}
+
+func TestProvider_VerifyMembership_TransportFailureIsNotErrNotMember(t *testing.T) {
+	nameByID := func(id int64) (string, error) { return "acme", nil }
+
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			w.Write([]byte(`{"uuid": "{user-uuid}"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}, nameByID)
+
+	_, err := p.VerifyMembership(context.Background(), "tok", WorkspaceID("acme"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errors.Is(err, scmauth.ErrNotMember) {
+		t.Fatalf("a 500 from the membership API must not be reported as scmauth.ErrNotMember, got %v", err)
+	}
+}
+
+func TestProvider_VerifyMembership_UnknownID(t *testing.T) {
+	nameByID := func(id int64) (string, error) { return "", fmt.Errorf("no configured workspace with id %d", id) }
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("no HTTP call should be made for an unresolvable id, got %s", r.URL.Path)
+	}, nameByID)
+
+	if _, err := p.VerifyMembership(context.Background(), "tok", 999); err == nil {
+		t.Fatal("expected an error resolving an unknown workspace id")
+	}
+}
@@ -0,0 +1,196 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/builtin/credential/azuredevops"
+	"github.com/hashicorp/vault/builtin/credential/bitbucket"
+	"github.com/hashicorp/vault/builtin/credential/gitlab"
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/oauth2"
+)
+
+// installationTokenSkew is subtracted from an installation token's reported
+// expiry so it gets refreshed slightly before GitHub actually rejects it.
+const installationTokenSkew = time.Minute
+
+const backendHelp = `
+The GitHub credential provider allows authentication against Vault using
+a GitHub personal access token (or, with the gitlab/bitbucket/azuredevops
+provider option, the equivalent token for that SCM). Users are associated
+with a configured organization and, optionally, specific teams.
+`
+
+type backend struct {
+	*framework.Backend
+
+	logger hclog.Logger
+
+	// appTokenMu guards the cached App installation token, minted lazily
+	// by Client and reused until it's close to expiring.
+	appTokenMu     sync.Mutex
+	appToken       string
+	appTokenExpiry time.Time
+}
+
+// Backend constructs the github auth backend.
+func Backend() *backend {
+	b := &backend{}
+
+	b.Backend = &framework.Backend{
+		Help: backendHelp,
+		Paths: []*framework.Path{
+			pathConfig(b),
+			pathLogin(b),
+		},
+		AuthRenew:   b.pathLoginRenew,
+		BackendType: logical.TypeCredential,
+	}
+
+	return b
+}
+
+func (b *backend) Logger() hclog.Logger {
+	if b.logger == nil {
+		b.logger = hclog.NewNullLogger()
+	}
+	return b.logger
+}
+
+// Client returns a *github.Client configured for c's base URL and, when
+// set, Enterprise TLS trust. c may be nil, in which case the returned
+// client talks to api.github.com with http.DefaultClient's default trust.
+//
+// If token is non-empty it's used as-is (the caller already has a
+// credential, e.g. the end user's token at login time). Otherwise, if c is
+// configured to authenticate as a GitHub App, Client mints (and caches)
+// an installation access token and uses that; this lets config-time
+// organization lookups work without an admin personal access token.
+func (b *backend) Client(ctx context.Context, c *config, token string) (*github.Client, error) {
+	base := http.DefaultClient
+	if c != nil {
+		var err error
+		base, err = c.httpClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if token == "" && c != nil && c.isAppConfigured() {
+		appToken, err := b.appInstallationToken(ctx, c, base)
+		if err != nil {
+			return nil, err
+		}
+		token = appToken
+	}
+
+	httpClient := base
+	if token != "" {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, base)
+		httpClient = oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+	}
+
+	client := github.NewClient(httpClient)
+	if c != nil && c.BaseURL != "" {
+		if parsed, err := url.Parse(c.BaseURL); err == nil {
+			client.BaseURL = parsed
+		}
+	}
+
+	return client, nil
+}
+
+// appInstallationToken returns a cached App installation token, minting a
+// new one via c.installationToken when none is cached or the cached one
+// is close to expiring.
+func (b *backend) appInstallationToken(ctx context.Context, c *config, httpClient *http.Client) (string, error) {
+	b.appTokenMu.Lock()
+	defer b.appTokenMu.Unlock()
+
+	if b.appToken != "" && time.Now().Before(b.appTokenExpiry.Add(-installationTokenSkew)) {
+		return b.appToken, nil
+	}
+
+	token, expiry, err := c.installationToken(ctx, httpClient)
+	if err != nil {
+		return "", err
+	}
+
+	b.appToken = token
+	b.appTokenExpiry = expiry
+	return token, nil
+}
+
+// providerFor builds the scmauth.Provider that backs c's configured SCM.
+// gitlab, bitbucket, and azuredevops are dispatched to their own
+// packages; github (the default) uses this package's own provider, wired
+// to the same b.Client used elsewhere so Enterprise/App auth settings
+// stay consistent between config-time org lookups and login-time
+// membership checks.
+func (b *backend) providerFor(ctx context.Context, storage logical.Storage, c *config) (scmauth.Provider, error) {
+	switch c.Provider {
+	case providerGitlab:
+		base, err := c.providerBaseURL("https://gitlab.com/")
+		if err != nil {
+			return nil, err
+		}
+		return gitlab.New(base, http.DefaultClient), nil
+
+	case providerBitbucket:
+		base, err := c.providerBaseURL("https://api.bitbucket.org/2.0/")
+		if err != nil {
+			return nil, err
+		}
+		return bitbucket.New(base, http.DefaultClient, b.nameByOrgID(ctx, storage)), nil
+
+	case providerAzureDevOps:
+		return azuredevops.New(http.DefaultClient, b.nameByOrgID(ctx, storage)), nil
+
+	default:
+		newClient := func(token string) (*github.Client, error) {
+			return b.Client(ctx, c, token)
+		}
+		return newGithubProvider(c, newClient), nil
+	}
+}
+
+// nameByOrgID returns a resolver that maps an org ID (as returned by
+// Provider.ResolveOrgID) back to the org name the operator configured,
+// by reading it from this mount's persisted config -- not an in-memory
+// cache, so it survives restarts and works from any freshly constructed
+// Provider.
+func (b *backend) nameByOrgID(ctx context.Context, storage logical.Storage) func(int64) (string, error) {
+	return func(id int64) (string, error) {
+		c, err := b.Config(ctx, storage)
+		if err != nil {
+			return "", err
+		}
+		if c == nil {
+			return "", fmt.Errorf("auth method not configured")
+		}
+		org := c.organizationByID(id)
+		if org == nil {
+			return "", fmt.Errorf("no configured organization with id %d", id)
+		}
+		return org.Name, nil
+	}
+}
+
+// providerBaseURL returns c.BaseURL parsed as a URL, falling back to
+// fallback when base_url wasn't configured.
+func (c *config) providerBaseURL(fallback string) (*url.URL, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = fallback
+	}
+	return url.Parse(base)
+}
@@ -2,30 +2,75 @@ package github
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/go-github/github"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/helper/tokenutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
+// installationTokenPath is the GitHub API path used to exchange an App JWT
+// for a short-lived installation access token.
+const installationTokenPath = "app/installations/%d/access_tokens"
+
+// Valid values for the team_name_field config option, controlling which
+// team identifier is compared against auth/github/map/teams/* entries.
+const (
+	teamNameFieldName = "name"
+	teamNameFieldSlug = "slug"
+	teamNameFieldBoth = "both"
+)
+
+// Valid values for the provider config option. This package only
+// implements "github" itself; the others are implemented by the sibling
+// gitlab, bitbucket, and azuredevops packages, which share this mount's
+// config, token-policy, and login plumbing via the Provider interface.
+const (
+	providerGithub      = "github"
+	providerGitlab      = "gitlab"
+	providerBitbucket   = "bitbucket"
+	providerAzureDevOps = "azuredevops"
+)
+
+var validProviders = []string{providerGithub, providerGitlab, providerBitbucket, providerAzureDevOps}
+
 func pathConfig(b *backend) *framework.Path {
 	p := &framework.Path{
 		Pattern: "config",
 		Fields: map[string]*framework.FieldSchema{
 			"organization": {
 				Type:        framework.TypeString,
-				Description: "The organization users must be part of",
-				Required:    true,
+				Description: "The organization users must be part of. Deprecated, use organizations instead.",
 			},
 			"organization_id": {
 				Type:        framework.TypeInt64,
-				Description: "The ID of the organization users must be part of",
+				Description: "The ID of the organization users must be part of. Deprecated, use organizations instead.",
+			},
+			"organizations": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `The list of organizations users must be part of. A login
+succeeds if the user belongs to any one of them. Replaces the singular
+"organization" parameter, which is retained for backwards compatibility.`,
+			},
+			"organization_token_policies": {
+				Type: framework.TypeKVPairs,
+				Description: `A map of organization name to a comma-separated list of
+additional token policies to apply to users who authenticate against that
+organization, on top of the mount-wide token_policies.`,
 			},
 			"base_url": {
 				Type: framework.TypeString,
@@ -37,6 +82,77 @@ API-compatible authentication server.`,
 					Group: "GitHub Options",
 				},
 			},
+			"hostname": {
+				Type: framework.TypeString,
+				Description: `The hostname of a GitHub Enterprise instance. Used to
+compose base_url (as https://<hostname>/api/v3/) when base_url is not set
+directly.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Hostname",
+					Group: "GitHub Options",
+				},
+			},
+			"root_ca_certificate": {
+				Type: framework.TypeString,
+				Description: `The PEM-format CA certificate bundle to use when
+verifying the TLS connection to a GitHub Enterprise instance that
+terminates behind an internal PKI.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Root CA Certificate",
+					Group: "GitHub Options",
+				},
+			},
+			"app_id": {
+				Type: framework.TypeInt64,
+				Description: `The GitHub App ID to authenticate as, instead of a
+user-scoped personal access token. Requires installation_id and
+private_key.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "App ID",
+					Group: "GitHub Options",
+				},
+			},
+			"installation_id": {
+				Type:        framework.TypeInt64,
+				Description: "The ID of the App installation to mint installation tokens for.",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Installation ID",
+					Group: "GitHub Options",
+				},
+			},
+			"private_key": {
+				Type: framework.TypeString,
+				Description: `The PEM-format RSA private key of the GitHub App,
+used to sign the JWT exchanged for installation tokens. Never returned by
+a read of this endpoint.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Private Key",
+					Group: "GitHub Options",
+				},
+			},
+			"provider": {
+				Type:    framework.TypeString,
+				Default: providerGithub,
+				Description: `Which SCM organization-membership Provider
+implementation backs this mount: "github", "gitlab", "bitbucket", or
+"azuredevops". Defaults to "github". The config, token policy, and login
+semantics are identical regardless of the provider.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Provider",
+					Group: "GitHub Options",
+				},
+			},
+			"team_name_field": {
+				Type:    framework.TypeString,
+				Default: teamNameFieldName,
+				Description: `Which team identifier to match against
+auth/github/map/teams/* when mapping policies: "name", "slug", or "both".
+Defaults to "name".`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Team Name Field",
+					Group: "GitHub Options",
+				},
+			},
 			"ttl": {
 				Type:        framework.TypeDurationSecond,
 				Description: tokenutil.DeprecationText("token_ttl"),
@@ -70,17 +186,101 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		c = &config{}
 	}
 
+	previousOrganization := c.Organization
+
 	if organizationRaw, ok := data.GetOk("organization"); ok {
 		c.Organization = organizationRaw.(string)
 	}
-	if c.Organization == "" {
-		return logical.ErrorResponse("organization is a required parameter"), nil
-	}
 
 	if organizationRaw, ok := data.GetOk("organization_id"); ok {
 		c.OrganizationID = organizationRaw.(int64)
 	}
 
+	organizationsProvided := false
+	if organizationsRaw, ok := data.GetOk("organizations"); ok {
+		organizationsProvided = true
+		names := organizationsRaw.([]string)
+		orgs := make([]*orgConfig, 0, len(names))
+		for _, name := range names {
+			orgs = append(orgs, &orgConfig{Name: name})
+		}
+		c.Organizations = orgs
+	}
+
+	c.applyLegacyOrganization(previousOrganization, organizationsProvided)
+
+	if len(c.Organizations) == 0 {
+		return logical.ErrorResponse("organization or organizations is a required parameter"), nil
+	}
+
+	if policiesRaw, ok := data.GetOk("organization_token_policies"); ok {
+		for name, policies := range policiesRaw.(map[string]string) {
+			org := c.organization(name)
+			if org == nil {
+				return logical.ErrorResponse(fmt.Sprintf("organization_token_policies references unknown organization %q", name)), nil
+			}
+			org.TokenPolicies = strutil.ParseDedupLowercaseAndSortStrings(policies, ",")
+		}
+	}
+
+	if hostnameRaw, ok := data.GetOk("hostname"); ok {
+		c.Hostname = hostnameRaw.(string)
+	}
+
+	if certRaw, ok := data.GetOk("root_ca_certificate"); ok {
+		cert := certRaw.(string)
+		if cert != "" {
+			pool := x509.NewCertPool()
+			if ok := pool.AppendCertsFromPEM([]byte(cert)); !ok {
+				return logical.ErrorResponse("failed to parse root_ca_certificate as PEM"), nil
+			}
+		}
+		c.RootCACertificate = cert
+	}
+
+	if appIDRaw, ok := data.GetOk("app_id"); ok {
+		c.AppID = appIDRaw.(int64)
+	}
+	if installationIDRaw, ok := data.GetOk("installation_id"); ok {
+		c.InstallationID = installationIDRaw.(int64)
+	}
+	if privateKeyRaw, ok := data.GetOk("private_key"); ok {
+		privateKey := privateKeyRaw.(string)
+		if privateKey != "" {
+			if _, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKey)); err != nil {
+				return logical.ErrorResponse(fmt.Sprintf("error parsing private_key: %s", err)), nil
+			}
+		}
+		c.PrivateKey = privateKey
+	}
+	if c.AppID != 0 || c.InstallationID != 0 || c.PrivateKey != "" {
+		if c.AppID == 0 || c.InstallationID == 0 || c.PrivateKey == "" {
+			return logical.ErrorResponse("app_id, installation_id, and private_key must all be set together"), nil
+		}
+	}
+
+	if providerRaw, ok := data.GetOk("provider"); ok {
+		c.Provider = providerRaw.(string)
+	}
+	if c.Provider == "" {
+		c.Provider = providerGithub
+	}
+	if !strutil.StrListContains(validProviders, c.Provider) {
+		return logical.ErrorResponse(fmt.Sprintf("invalid provider %q: must be one of %v", c.Provider, validProviders)), nil
+	}
+
+	if teamNameFieldRaw, ok := data.GetOk("team_name_field"); ok {
+		c.TeamNameField = teamNameFieldRaw.(string)
+	}
+	if c.TeamNameField == "" {
+		c.TeamNameField = teamNameFieldName
+	}
+	switch c.TeamNameField {
+	case teamNameFieldName, teamNameFieldSlug, teamNameFieldBoth:
+	default:
+		return logical.ErrorResponse(fmt.Sprintf("invalid team_name_field %q: must be %q, %q, or %q", c.TeamNameField, teamNameFieldName, teamNameFieldSlug, teamNameFieldBoth)), nil
+	}
+
 	var parsedURL *url.URL
 	if baseURLRaw, ok := data.GetOk("base_url"); ok {
 		baseURL := baseURLRaw.(string)
@@ -92,26 +292,63 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 			return logical.ErrorResponse(fmt.Sprintf("error parsing given base_url: %s", err)), nil
 		}
 		c.BaseURL = baseURL
-	}
-
-	if c.OrganizationID == 0 {
-		githubToken := os.Getenv("VAULT_AUTH_CONFIG_GITHUB_TOKEN")
-		client, err := b.Client(githubToken)
+	} else if c.Hostname != "" {
+		baseURL := fmt.Sprintf("https://%s/api/v3/", c.Hostname)
+		parsedURL, err = url.Parse(baseURL)
 		if err != nil {
-			return nil, err
+			return logical.ErrorResponse(fmt.Sprintf("error parsing base_url composed from hostname: %s", err)), nil
 		}
-		// ensure our client has the BaseURL if it was provided
-		if parsedURL != nil {
-			client.BaseURL = parsedURL
+		c.BaseURL = baseURL
+	}
+
+	// resolve every organization's numeric ID up front so that login-time
+	// verification stays a pure numeric-ID comparison; a single failed
+	// lookup fails the whole write. Resolution goes through the configured
+	// Provider so organizations = ["mygroup"] resolves against GitLab (or
+	// Bitbucket, or Azure DevOps) when provider is set accordingly, rather
+	// than always hitting the GitHub API.
+	if c.needsOrganizationIDs() {
+		switch c.Provider {
+		case "", providerGithub:
+			githubToken := os.Getenv("VAULT_AUTH_CONFIG_GITHUB_TOKEN")
+			client, err := b.Client(ctx, c, githubToken)
+			if err != nil {
+				return nil, err
+			}
+			// ensure our client has the BaseURL if it was provided
+			if parsedURL != nil {
+				client.BaseURL = parsedURL
+			}
+
+			if err := c.setOrganizationIDs(ctx, client); err != nil {
+				errorMsg := fmt.Errorf("unable to fetch the organization_id, you must manually set it in the config: %s", err)
+				b.Logger().Error(errorMsg.Error())
+				return nil, errorMsg
+			}
+
+		default:
+			provider, err := b.providerFor(ctx, req.Storage, c)
+			if err != nil {
+				return nil, err
+			}
+			for _, org := range c.Organizations {
+				if org.ID != 0 {
+					continue
+				}
+				id, err := provider.ResolveOrgID(ctx, org.Name)
+				if err != nil {
+					errorMsg := fmt.Errorf("unable to resolve the organization id for %q, you must manually set organizations[].id in the config: %s", org.Name, err)
+					b.Logger().Error(errorMsg.Error())
+					return nil, errorMsg
+				}
+				org.ID = id
+			}
 		}
 
-		// we want to set the Org ID in the config so we can use that to verify
-		// the credentials on login
-		err = c.setOrganizationID(ctx, client)
-		if err != nil {
-			errorMsg := fmt.Errorf("unable to fetch the organization_id, you must manually set it in the config: %s", err)
-			b.Logger().Error(errorMsg.Error())
-			return nil, errorMsg
+		// OrganizationID only backs the legacy read-only field now; login
+		// enforcement walks the full c.Organizations list (see path_login.go)
+		if len(c.Organizations) > 0 {
+			c.OrganizationID = c.Organizations[0].ID
 		}
 	}
 
@@ -155,10 +392,30 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, data
 		return nil, nil
 	}
 
+	orgs := make([]map[string]interface{}, 0, len(config.Organizations))
+	for _, org := range config.Organizations {
+		orgs = append(orgs, map[string]interface{}{
+			"name":           org.Name,
+			"id":             org.ID,
+			"token_policies": org.TokenPolicies,
+		})
+	}
+
 	d := map[string]interface{}{
-		"organization_id": config.OrganizationID,
-		"organization":    config.Organization,
-		"base_url":        config.BaseURL,
+		"organization_id":     config.OrganizationID,
+		"organization":        config.Organization,
+		"organizations":       orgs,
+		"base_url":            config.BaseURL,
+		"hostname":            config.Hostname,
+		"root_ca_certificate": config.RootCACertificate,
+		"app_id":              config.AppID,
+		"installation_id":     config.InstallationID,
+		"team_name_field":     config.TeamNameField,
+		"provider":            config.Provider,
+	}
+	if config.PrivateKey != "" {
+		sum := sha256.Sum256([]byte(config.PrivateKey))
+		d["private_key_fingerprint"] = hex.EncodeToString(sum[:])
 	}
 	config.PopulateTokenData(d)
 
@@ -204,25 +461,205 @@ func (b *backend) Config(ctx context.Context, s logical.Storage) (*config, error
 type config struct {
 	tokenutil.TokenParams
 
-	OrganizationID int64         `json:"organization_id" structs:"organization_id" mapstructure:"organization_id"`
-	Organization   string        `json:"organization" structs:"organization" mapstructure:"organization"`
-	BaseURL        string        `json:"base_url" structs:"base_url" mapstructure:"base_url"`
-	TTL            time.Duration `json:"ttl" structs:"ttl" mapstructure:"ttl"`
-	MaxTTL         time.Duration `json:"max_ttl" structs:"max_ttl" mapstructure:"max_ttl"`
+	OrganizationID    int64         `json:"organization_id" structs:"organization_id" mapstructure:"organization_id"`
+	Organization      string        `json:"organization" structs:"organization" mapstructure:"organization"`
+	Organizations     []*orgConfig  `json:"organizations" structs:"organizations" mapstructure:"organizations"`
+	BaseURL           string        `json:"base_url" structs:"base_url" mapstructure:"base_url"`
+	Hostname          string        `json:"hostname" structs:"hostname" mapstructure:"hostname"`
+	RootCACertificate string        `json:"root_ca_certificate" structs:"root_ca_certificate" mapstructure:"root_ca_certificate"`
+	AppID             int64         `json:"app_id" structs:"app_id" mapstructure:"app_id"`
+	InstallationID    int64         `json:"installation_id" structs:"installation_id" mapstructure:"installation_id"`
+	PrivateKey        string        `json:"private_key" structs:"private_key" mapstructure:"private_key"`
+	TeamNameField     string        `json:"team_name_field" structs:"team_name_field" mapstructure:"team_name_field"`
+	Provider          string        `json:"provider" structs:"provider" mapstructure:"provider"`
+	TTL               time.Duration `json:"ttl" structs:"ttl" mapstructure:"ttl"`
+	MaxTTL            time.Duration `json:"max_ttl" structs:"max_ttl" mapstructure:"max_ttl"`
 }
 
-func (c *config) setOrganizationID(ctx context.Context, client *github.Client) error {
-	org, _, err := client.Organizations.Get(ctx, c.Organization)
+// teamNames returns the identifier(s) of team that should be looked up
+// under auth/github/map/teams/*, according to the configured
+// team_name_field. "both" returns both the name and the slug so the
+// caller can match against either.
+func (c *config) teamNames(team *github.Team) []string {
+	switch c.TeamNameField {
+	case teamNameFieldSlug:
+		return []string{team.GetSlug()}
+	case teamNameFieldBoth:
+		return []string{team.GetName(), team.GetSlug()}
+	default:
+		return []string{team.GetName()}
+	}
+}
+
+// isAppConfigured reports whether the mount is configured to authenticate to
+// GitHub as an App installation rather than with a user-scoped token.
+func (c *config) isAppConfigured() bool {
+	return c.AppID != 0 && c.InstallationID != 0 && c.PrivateKey != ""
+}
+
+// installationToken mints a JWT for the configured App, signed with
+// PrivateKey, and exchanges it for a short-lived installation access token
+// that can be used in place of a personal access token when calling the
+// GitHub API. The caller is responsible for caching the returned token
+// until its expiry.
+func (c *config) installationToken(ctx context.Context, httpClient *http.Client) (string, time.Time, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(c.PrivateKey))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error parsing private_key: %w", err)
+	}
+
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(now.Add(-30 * time.Second)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+		Issuer:    strconv.FormatInt(c.AppID, 10),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error signing App JWT: %w", err)
+	}
+
+	base := c.BaseURL
+	if base == "" {
+		base = "https://api.github.com/"
+	}
+	endpoint := base + fmt.Sprintf(installationTokenPath, c.InstallationID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
 	if err != nil {
-		return err
+		return "", time.Time{}, fmt.Errorf("error building installation token request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+signed)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error exchanging App JWT for an installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", time.Time{}, fmt.Errorf("unexpected status %s minting installation token", resp.Status)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("error decoding installation token response: %w", err)
+	}
+
+	return body.Token, body.ExpiresAt, nil
+}
+
+// orgConfig is a single entry in config.Organizations. A login succeeds if
+// the authenticated user belongs to any one of them.
+type orgConfig struct {
+	Name          string   `json:"name" structs:"name" mapstructure:"name"`
+	ID            int64    `json:"id" structs:"id" mapstructure:"id"`
+	TokenPolicies []string `json:"token_policies,omitempty" structs:"token_policies" mapstructure:"token_policies"`
+}
+
+func (c *config) hasOrganization(name string) bool {
+	return c.organization(name) != nil
+}
+
+func (c *config) organization(name string) *orgConfig {
+	for _, org := range c.Organizations {
+		if org.Name == name {
+			return org
+		}
+	}
+	return nil
+}
+
+func (c *config) organizationByID(id int64) *orgConfig {
+	for _, org := range c.Organizations {
+		if org.ID == id {
+			return org
+		}
+	}
+	return nil
+}
+
+// applyLegacyOrganization folds the legacy singular Organization field into
+// Organizations, so that login and policy mapping only ever need to walk
+// the list. If the operator replaced Organization with a new value and
+// didn't resend organizations on this write, the previous value is removed
+// from Organizations instead of accumulating -- otherwise changing
+// organization from "acme" to "beta" would silently leave both trusted.
+func (c *config) applyLegacyOrganization(previous string, organizationsProvided bool) {
+	if !organizationsProvided && previous != "" && previous != c.Organization {
+		filtered := c.Organizations[:0]
+		for _, org := range c.Organizations {
+			if org.Name != previous {
+				filtered = append(filtered, org)
+			}
+		}
+		c.Organizations = filtered
+	}
+
+	if c.Organization != "" && !c.hasOrganization(c.Organization) {
+		c.Organizations = append(c.Organizations, &orgConfig{Name: c.Organization, ID: c.OrganizationID})
+	}
+}
+
+func (c *config) needsOrganizationIDs() bool {
+	for _, org := range c.Organizations {
+		if org.ID == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// httpClient builds an *http.Client whose Transport trusts
+// RootCACertificate, for talking to a GitHub Enterprise instance that
+// terminates TLS behind an internal PKI. It returns http.DefaultClient
+// unmodified when no root_ca_certificate is configured.
+func (c *config) httpClient() (*http.Client, error) {
+	if c.RootCACertificate == "" {
+		return http.DefaultClient, nil
 	}
 
-	orgID := org.GetID()
-	if orgID == 0 {
-		return fmt.Errorf("organization_id not found for %s", c.Organization)
+	pool := x509.NewCertPool()
+	if ok := pool.AppendCertsFromPEM([]byte(c.RootCACertificate)); !ok {
+		return nil, fmt.Errorf("failed to parse configured root_ca_certificate as PEM")
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: pool,
+			},
+		},
+	}, nil
+}
+
+// setOrganizationIDs resolves the numeric ID of every configured
+// organization through a single GitHub API lookup each, so that
+// verifying membership at login time stays a pure numeric-ID comparison.
+// It refuses to apply any IDs if a single lookup fails.
+func (c *config) setOrganizationIDs(ctx context.Context, client *github.Client) error {
+	ids := make([]int64, len(c.Organizations))
+	for i, org := range c.Organizations {
+		ghOrg, _, err := client.Organizations.Get(ctx, org.Name)
+		if err != nil {
+			return fmt.Errorf("error looking up organization %q: %w", org.Name, err)
+		}
+
+		orgID := ghOrg.GetID()
+		if orgID == 0 {
+			return fmt.Errorf("organization_id not found for %s", org.Name)
+		}
+
+		ids[i] = orgID
 	}
 
-	c.OrganizationID = orgID
+	for i, org := range c.Organizations {
+		org.ID = ids[i]
+	}
 
 	return nil
 }
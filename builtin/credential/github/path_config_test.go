@@ -0,0 +1,81 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestConfig_ApplyLegacyOrganization(t *testing.T) {
+	t.Run("folds a fresh legacy organization into Organizations", func(t *testing.T) {
+		c := &config{Organization: "acme"}
+		c.applyLegacyOrganization("", false)
+
+		if !c.hasOrganization("acme") {
+			t.Fatalf("expected acme to be folded into Organizations, got %#v", c.Organizations)
+		}
+		if len(c.Organizations) != 1 {
+			t.Fatalf("expected exactly one organization, got %#v", c.Organizations)
+		}
+	})
+
+	t.Run("replaces rather than widens when organization changes without organizations", func(t *testing.T) {
+		c := &config{
+			Organization:  "beta",
+			Organizations: []*orgConfig{{Name: "acme"}},
+		}
+		c.applyLegacyOrganization("acme", false)
+
+		if c.hasOrganization("acme") {
+			t.Fatalf("expected acme to be dropped, got %#v", c.Organizations)
+		}
+		if !c.hasOrganization("beta") {
+			t.Fatalf("expected beta to be present, got %#v", c.Organizations)
+		}
+		if len(c.Organizations) != 1 {
+			t.Fatalf("expected exactly one organization, got %#v", c.Organizations)
+		}
+	})
+
+	t.Run("leaves Organizations alone when organizations was resent this write", func(t *testing.T) {
+		c := &config{
+			Organization:  "beta",
+			Organizations: []*orgConfig{{Name: "acme"}, {Name: "beta"}},
+		}
+		c.applyLegacyOrganization("acme", true)
+
+		if !c.hasOrganization("acme") || !c.hasOrganization("beta") {
+			t.Fatalf("expected both organizations to remain, got %#v", c.Organizations)
+		}
+	})
+}
+
+func TestConfig_TeamNames(t *testing.T) {
+	team := &github.Team{
+		Name: github.String("Core Engineers"),
+		Slug: github.String("core-engineers"),
+	}
+
+	cases := []struct {
+		field string
+		want  []string
+	}{
+		{teamNameFieldName, []string{"Core Engineers"}},
+		{teamNameFieldSlug, []string{"core-engineers"}},
+		{teamNameFieldBoth, []string{"Core Engineers", "core-engineers"}},
+		{"", []string{"Core Engineers"}},
+	}
+
+	for _, tc := range cases {
+		c := &config{TeamNameField: tc.field}
+		got := c.teamNames(team)
+		if len(got) != len(tc.want) {
+			t.Fatalf("team_name_field %q: expected %v, got %v", tc.field, tc.want, got)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("team_name_field %q: expected %v, got %v", tc.field, tc.want, got)
+			}
+		}
+	}
+}
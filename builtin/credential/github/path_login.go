@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathLogin(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "login",
+		Fields: map[string]*framework.FieldSchema{
+			"token": {
+				Type:        framework.TypeString,
+				Description: "GitHub personal access token, or the equivalent token for the configured provider.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathLogin,
+		},
+	}
+}
+
+func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	token := data.Get("token").(string)
+	if token == "" {
+		return logical.ErrorResponse("missing token"), nil
+	}
+
+	c, err := b.Config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return logical.ErrorResponse("auth method not configured"), nil
+	}
+
+	provider, err := b.providerFor(ctx, req.Storage, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// login succeeds if the token's owner belongs to any one of the
+	// configured organizations; the first one they belong to wins for
+	// the purposes of org-scoped token_policies
+	var org *orgConfig
+	var teams []string
+	for _, candidate := range c.Organizations {
+		candidateTeams, err := provider.VerifyMembership(ctx, token, candidate.ID)
+		switch {
+		case err == nil:
+			org, teams = candidate, candidateTeams
+		case errors.Is(err, scmauth.ErrNotMember):
+			continue
+		default:
+			return nil, err
+		}
+		if org != nil {
+			break
+		}
+	}
+	if org == nil {
+		return logical.ErrorResponse("user is not in any configured organization"), nil
+	}
+
+	policies := append([]string{}, org.TokenPolicies...)
+	for _, team := range teams {
+		teamPolicies, err := b.teamPolicies(ctx, req.Storage, team)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, teamPolicies...)
+	}
+
+	auth := &logical.Auth{
+		Metadata: map[string]string{
+			"org":   org.Name,
+			"teams": strings.Join(teams, ","),
+		},
+		DisplayName: org.Name,
+		Policies:    policies,
+	}
+	c.PopulateTokenAuth(auth)
+
+	return &logical.Response{Auth: auth}, nil
+}
+
+func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	c, err := b.Config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, nil
+	}
+
+	resp := &logical.Response{Auth: req.Auth}
+	resp.Auth.TTL = c.TokenTTL
+	resp.Auth.MaxTTL = c.TokenMaxTTL
+	return resp, nil
+}
+
+// teamPolicies reads the policies mapped to team from the storage entry
+// map/teams/<team>, with team lowercased.
+func (b *backend) teamPolicies(ctx context.Context, storage logical.Storage, team string) ([]string, error) {
+	entry, err := storage.Get(ctx, "map/teams/"+strings.ToLower(team))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var stored struct {
+		Value string `json:"value"`
+	}
+	if err := entry.DecodeJSON(&stored); err != nil {
+		return nil, err
+	}
+
+	return strutil.ParseDedupLowercaseAndSortStrings(stored.Value, ","), nil
+}
@@ -0,0 +1,95 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/github"
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+)
+
+// Provider is an alias of scmauth.Provider, kept so existing references
+// to github.Provider continue to resolve. The interface itself lives in
+// the scmauth package so that this package can dispatch to the gitlab,
+// bitbucket, and azuredevops implementations without an import cycle.
+type Provider = scmauth.Provider
+
+// githubProvider is the Provider implementation backed by *github.Client,
+// and is the default used by this package's own pathConfig. newClient is
+// supplied by the backend so the provider picks up the same base URL,
+// Enterprise TLS trust, and App-vs-PAT authentication as the rest of the
+// mount.
+type githubProvider struct {
+	config    *config
+	newClient func(token string) (*github.Client, error)
+}
+
+func newGithubProvider(c *config, newClient func(token string) (*github.Client, error)) *githubProvider {
+	return &githubProvider{config: c, newClient: newClient}
+}
+
+func (p *githubProvider) ResolveOrgID(ctx context.Context, name string) (int64, error) {
+	client, err := p.newClient("")
+	if err != nil {
+		return 0, err
+	}
+
+	org, _, err := client.Organizations.Get(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	return org.GetID(), nil
+}
+
+func (p *githubProvider) VerifyMembership(ctx context.Context, token string, orgID int64) ([]string, error) {
+	org := p.config.organizationByID(orgID)
+	if org == nil {
+		return nil, fmt.Errorf("unknown organization id %d", orgID)
+	}
+
+	client, err := p.newClient(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetOrgMembership with an empty username checks the token holder's
+	// own membership, and 404s if they don't belong to the org. Any other
+	// error (auth failure, rate limit, outage) is a real failure and must
+	// not be mistaken for "not a member".
+	membership, resp, err := client.Organizations.GetOrgMembership(ctx, "", org.Name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, scmauth.ErrNotMember
+		}
+		return nil, fmt.Errorf("error checking organization membership: %w", err)
+	}
+	if membership.GetState() != "active" {
+		return nil, scmauth.ErrNotMember
+	}
+
+	teams, _, err := client.Teams.ListUserTeams(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, team := range teams {
+		if team.GetOrganization().GetID() != orgID {
+			continue
+		}
+		names = append(names, p.config.teamNames(team)...)
+	}
+
+	return names, nil
+}
+
+func (p *githubProvider) BaseAPI() *url.URL {
+	client, err := p.newClient("")
+	if err != nil {
+		return nil
+	}
+	return client.BaseURL
+}
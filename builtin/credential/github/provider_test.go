@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+)
+
+func newTestGithubProvider(t *testing.T, handler http.HandlerFunc) *githubProvider {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	b := Backend()
+	c := &config{BaseURL: ts.URL + "/", Organizations: []*orgConfig{{Name: "acme", ID: 1}}}
+	newClient := func(token string) (*github.Client, error) {
+		return b.Client(context.Background(), c, token)
+	}
+	return newGithubProvider(c, newClient)
+}
+
+func TestProvider_VerifyMembership_Member(t *testing.T) {
+	p := newTestGithubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/memberships/orgs/acme":
+			fmt.Fprint(w, `{"state": "active"}`)
+		case "/user/teams":
+			fmt.Fprint(w, `[{"name": "platform", "organization": {"id": 1}}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	teams, err := p.VerifyMembership(context.Background(), "tok", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(teams) != 1 || teams[0] != "platform" {
+		t.Fatalf("unexpected teams %v", teams)
+	}
+}
+
+func TestProvider_VerifyMembership_NotMember(t *testing.T) {
+	p := newTestGithubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/memberships/orgs/acme":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	if _, err := p.VerifyMembership(context.Background(), "tok", 1); !errors.Is(err, scmauth.ErrNotMember) {
+		t.Fatalf("expected scmauth.ErrNotMember, got %v", err)
+	}
+}
+
+func TestProvider_VerifyMembership_TransportFailureIsNotErrNotMember(t *testing.T) {
+	p := newTestGithubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/memberships/orgs/acme":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	_, err := p.VerifyMembership(context.Background(), "tok", 1)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errors.Is(err, scmauth.ErrNotMember) {
+		t.Fatalf("a 500 from the membership API must not be reported as scmauth.ErrNotMember, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package gitlab implements the scmauth.Provider interface against the
+// GitLab REST API, so that the shared SCM organization-membership config,
+// token-policy, and login plumbing in builtin/credential/github can be
+// reused for GitLab groups instead of GitHub organizations.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+)
+
+// errNotFound distinguishes a 404 from the GitLab API (the group or
+// membership genuinely doesn't exist) from any other failure, so callers
+// can tell "not a member" apart from a transport or auth error.
+var errNotFound = errors.New("gitlab: not found")
+
+var _ scmauth.Provider = (*Provider)(nil)
+
+// Provider resolves GitLab groups and their subgroups, which play the
+// role that organizations and teams play for the github package. GitLab
+// groups have a real numeric ID, so unlike the bitbucket and azuredevops
+// providers this one needs no separate id-to-name cache.
+type Provider struct {
+	baseURL *url.URL
+	http    *http.Client
+}
+
+// New returns a Provider that talks to the GitLab instance at baseURL
+// (e.g. https://gitlab.com/ or a self-managed instance). httpClient may
+// be nil, in which case http.DefaultClient is used.
+func New(baseURL *url.URL, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Provider{baseURL: baseURL, http: httpClient}
+}
+
+func (p *Provider) BaseAPI() *url.URL {
+	return p.baseURL
+}
+
+func (p *Provider) ResolveOrgID(ctx context.Context, name string) (int64, error) {
+	var group struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.get(ctx, "", "groups/"+url.PathEscape(name), &group); err != nil {
+		return 0, err
+	}
+	if group.ID == 0 {
+		return 0, fmt.Errorf("group_id not found for %s", name)
+	}
+
+	return group.ID, nil
+}
+
+func (p *Provider) VerifyMembership(ctx context.Context, token string, orgID int64) ([]string, error) {
+	var me struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.get(ctx, token, "user", &me); err != nil {
+		return nil, err
+	}
+
+	var membership struct {
+		ID int64 `json:"id"`
+	}
+	if err := p.get(ctx, token, fmt.Sprintf("groups/%d/members/all/%d", orgID, me.ID), &membership); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, scmauth.ErrNotMember
+		}
+		return nil, fmt.Errorf("error checking group membership: %w", err)
+	}
+
+	var subgroups []struct {
+		Name string `json:"name"`
+	}
+	if err := p.get(ctx, token, fmt.Sprintf("groups/%d/subgroups", orgID), &subgroups); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(subgroups))
+	for _, sg := range subgroups {
+		names = append(names, sg.Name)
+	}
+
+	return names, nil
+}
+
+func (p *Provider) get(ctx context.Context, token, path string, out interface{}) error {
+	endpoint := fmt.Sprintf("%sapi/v4/%s", p.baseURL.String(), path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, path)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,103 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/vault/builtin/credential/scmauth"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	base, err := url.Parse(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return New(base, ts.Client())
+}
+
+func TestProvider_ResolveOrgID(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/groups/acme" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"id": 42}`))
+	})
+
+	id, err := p.ResolveOrgID(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 42 {
+		t.Fatalf("expected id 42, got %d", id)
+	}
+}
+
+func TestProvider_VerifyMembership_Member(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/user":
+			w.Write([]byte(`{"id": 7}`))
+		case "/api/v4/groups/42/members/all/7":
+			w.Write([]byte(`{"id": 7}`))
+		case "/api/v4/groups/42/subgroups":
+			w.Write([]byte(`[{"name": "platform"}, {"name": "infra"}]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	teams, err := p.VerifyMembership(context.Background(), "tok", 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(teams) != 2 || teams[0] != "platform" || teams[1] != "infra" {
+		t.Fatalf("unexpected teams %v", teams)
+	}
+}
+
+func TestProvider_VerifyMembership_NotMember(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/user":
+			w.Write([]byte(`{"id": 7}`))
+		case "/api/v4/groups/42/members/all/7":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	_, err := p.VerifyMembership(context.Background(), "tok", 42)
+	if !errors.Is(err, scmauth.ErrNotMember) {
+		t.Fatalf("expected scmauth.ErrNotMember, got %v", err)
+	}
+}
+
+func TestProvider_VerifyMembership_TransportFailureIsNotErrNotMember(t *testing.T) {
+	p := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/user":
+			w.Write([]byte(`{"id": 7}`))
+		case "/api/v4/groups/42/members/all/7":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	_, err := p.VerifyMembership(context.Background(), "tok", 42)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errors.Is(err, scmauth.ErrNotMember) {
+		t.Fatalf("a 500 from the membership API must not be reported as scmauth.ErrNotMember, got %v", err)
+	}
+}
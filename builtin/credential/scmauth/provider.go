@@ -0,0 +1,39 @@
+// Package scmauth defines the Provider interface shared by the github,
+// gitlab, bitbucket, and azuredevops auth backends. It lives outside all
+// four packages so that the github backend (which dispatches to every
+// provider) can import the others without an import cycle.
+package scmauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ErrNotMember is returned by VerifyMembership when the token's owner
+// authenticated successfully but does not belong to orgID. Callers use
+// errors.Is(err, ErrNotMember) to distinguish "not a member" (try the
+// next configured organization) from a transport or API failure (fail
+// the login).
+var ErrNotMember = errors.New("scmauth: token does not belong to organization")
+
+// Provider abstracts the SCM-specific calls needed to resolve an
+// organization's (or group's, or workspace's) numeric ID and verify a
+// user's membership in it, so that the shared config, token-policy, and
+// login plumbing in builtin/credential/github can be reused across SCMs.
+type Provider interface {
+	// ResolveOrgID looks up the numeric ID of the named organization, so
+	// that membership can be verified with a pure ID comparison at
+	// login time instead of a second name-based lookup.
+	ResolveOrgID(ctx context.Context, name string) (int64, error)
+
+	// VerifyMembership checks that the holder of token belongs to
+	// orgID, returning the names of the teams (or groups, or projects)
+	// they belong to within it. It returns ErrNotMember (wrapped or
+	// bare) if the token is valid but its owner isn't a member of
+	// orgID.
+	VerifyMembership(ctx context.Context, token string, orgID int64) (teams []string, err error)
+
+	// BaseAPI returns the base URL this provider talks to.
+	BaseAPI() *url.URL
+}
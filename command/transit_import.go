@@ -0,0 +1,192 @@
+package command
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/google/tink/go/kwp/subtle"
+	"github.com/hashicorp/vault/api"
+)
+
+// importKey backs both `vault transit import` and `vault transit
+// import-version`: it fetches the target key's RSA wrapping key (or, with
+// opts.TTL set, requests a short-lived single-use one scoped to
+// opts.Nonce/opts.Audience), wraps KEY for it per Vault's documented BYOK
+// wrapping scheme, and submits the result to operation ("import" or
+// "import_version") under PATH.
+func importKey(c *BaseCommand, operation string, flags *FlagSets, args []string, opts wrappingKeyOptions) int {
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		c.UI.Error(fmt.Sprintf("Not enough arguments: expected PATH and KEY, got %d", len(args)))
+		return 1
+	}
+
+	path := sanitizePath(args[0])
+
+	keyMaterial, err := readKeyMaterial(args[1])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading key material: %s", err))
+		return 2
+	}
+
+	data, err := parseArgsData(os.Stdin, args[2:])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error parsing additional arguments: %s", err))
+		return 1
+	}
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 2
+	}
+
+	wrappingKey, err := fetchWrappingKey(client, path, opts)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error fetching wrapping key: %s", err))
+		return 2
+	}
+
+	ciphertext, err := wrapKeyForImport(wrappingKey, keyMaterial)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error wrapping key material: %s", err))
+		return 2
+	}
+	data["ciphertext"] = ciphertext
+	if opts.TTL != 0 {
+		// Echo back the nonce/audience the wrapping key was scoped to, so
+		// the server can reject this payload if it doesn't match what
+		// wrapping_key/generate issued it for.
+		if _, ok := data["nonce"]; !ok {
+			data["nonce"] = opts.Nonce
+		}
+		if _, ok := data["audience"]; !ok {
+			data["audience"] = opts.Audience
+		}
+	}
+
+	secret, err := client.Logical().Write(fmt.Sprintf("%s/%s", path, operation), data)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing data to %s/%s: %s", path, operation, err))
+		return 2
+	}
+	if secret == nil {
+		return 0
+	}
+
+	return OutputSecret(c.UI, secret)
+}
+
+// readKeyMaterial returns the raw bytes of a base64 encoded key, given
+// either directly on the command line or, via "@path" notation, read from
+// the file at path.
+func readKeyMaterial(raw string) ([]byte, error) {
+	if strings.HasPrefix(raw, "@") {
+		contents, err := ioutil.ReadFile(strings.TrimPrefix(raw, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", raw, err)
+		}
+		raw = strings.TrimSpace(string(contents))
+	}
+
+	key, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("key material must be base64 encoded: %w", err)
+	}
+
+	return key, nil
+}
+
+// fetchWrappingKey retrieves the PEM-encoded RSA public wrapping key used to
+// protect key material in transit to the server. With opts.TTL set, it
+// requests a short-lived, single-use key bound to opts.Nonce/opts.Audience
+// from wrapping_key/generate instead of the target key's long-lived public
+// wrapping key, so a leaked wrapped payload can't be replayed against a
+// later import.
+func fetchWrappingKey(client *api.Client, path string, opts wrappingKeyOptions) (*rsa.PublicKey, error) {
+	var secret *api.Secret
+	var err error
+
+	if opts.TTL != 0 {
+		secret, err = client.Logical().Write(path+"/wrapping_key/generate", map[string]interface{}{
+			"ttl":      opts.TTL.String(),
+			"nonce":    opts.Nonce,
+			"audience": opts.Audience,
+		})
+	} else {
+		secret, err = client.Logical().Read(path + "/wrapping_key")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data["public_key"] == nil {
+		return nil, fmt.Errorf("no wrapping key returned from %s", path)
+	}
+
+	pemKey, ok := secret.Data["public_key"].(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T for public_key", secret.Data["public_key"])
+	}
+
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("error decoding wrapping key as PEM")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing wrapping key: %w", err)
+	}
+
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("wrapping key is not an RSA public key")
+	}
+
+	return rsaPub, nil
+}
+
+// wrapKeyForImport wraps keyMaterial for wrappingKey following Vault's
+// documented BYOK wrapping procedure: an ephemeral AES-256 key wraps
+// keyMaterial with AES-KWP, and the ephemeral key is itself wrapped with
+// wrappingKey via RSA-OAEP; the two ciphertexts are concatenated (wrapped
+// AES key first) and base64 encoded, which is the format the transit
+// import/import_version endpoints expect.
+func wrapKeyForImport(wrappingKey *rsa.PublicKey, keyMaterial []byte) (string, error) {
+	ephemeralAESKey := make([]byte, 32)
+	if _, err := rand.Read(ephemeralAESKey); err != nil {
+		return "", fmt.Errorf("error generating ephemeral AES key: %w", err)
+	}
+
+	kwp, err := subtle.NewKWP(ephemeralAESKey)
+	if err != nil {
+		return "", fmt.Errorf("error initializing AES-KWP: %w", err)
+	}
+	wrappedTargetKey, err := kwp.Wrap(keyMaterial)
+	if err != nil {
+		return "", fmt.Errorf("error wrapping key material: %w", err)
+	}
+
+	wrappedAESKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, wrappingKey, ephemeralAESKey, []byte{})
+	if err != nil {
+		return "", fmt.Errorf("error wrapping ephemeral AES key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(append(wrappedAESKey, wrappedTargetKey...)), nil
+}
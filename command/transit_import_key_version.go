@@ -2,6 +2,7 @@ package command
 
 import (
 	"strings"
+	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
@@ -14,6 +15,21 @@ var (
 
 type TransitImportVersionCommand struct {
 	*BaseCommand
+
+	flagWrappingKeyTTL      time.Duration
+	flagWrappingKeyNonce    string
+	flagWrappingKeyAudience string
+}
+
+// wrappingKeyOptions scopes the wrapping key that importKey requests from
+// the wrapping_key/generate endpoint to a single import operation: it
+// expires after TTL and the server only accepts it back with a matching
+// Nonce/Audience, so a leaked wrapping payload can't be replayed against a
+// second import_version call.
+type wrappingKeyOptions struct {
+	TTL      time.Duration
+	Nonce    string
+	Audience string
 }
 
 func (c *TransitImportVersionCommand) Synopsis() string {
@@ -33,13 +49,49 @@ Usage: vault transit import-version PATH KEY [...]
   (such as the PKCS#11 mechanism CKM_RSA_AES_KEY_WRAP), you should use it
   directly rather than this command.
 
+  By default the wrapping key used to wrap the material is the target key's
+  long-lived public wrapping key. Pass -wrapping-key-ttl (and optionally
+  -wrapping-key-nonce/-wrapping-key-audience) to instead request a
+  short-lived, single-use wrapping key scoped to this import:
+
+      $ vault transit import-version transit/keys/my-key @key.b64 \
+          -wrapping-key-ttl=5m \
+          -wrapping-key-nonce=4c0f3c7d \
+          -wrapping-key-audience=hsm-transfer
+
 ` + c.Flags().Help()
 
 	return strings.TrimSpace(helpText)
 }
 
 func (c *TransitImportVersionCommand) Flags() *FlagSets {
-	return c.flagSet(FlagSetHTTP)
+	set := c.flagSet(FlagSetHTTP)
+	f := set.NewFlagSet("Command Options")
+
+	f.DurationVar(&DurationVar{
+		Name:   "wrapping-key-ttl",
+		Target: &c.flagWrappingKeyTTL,
+		Usage: `Request a per-operation wrapping key valid for this long,
+instead of using the target key's long-lived public wrapping key. Requires
+the Transit or Transform mount to support wrapping_key/generate.`,
+	})
+
+	f.StringVar(&StringVar{
+		Name:   "wrapping-key-nonce",
+		Target: &c.flagWrappingKeyNonce,
+		Usage: `A nonce the requested wrapping key will be bound to; the
+import payload must echo it back, so the server rejects a wrap that was
+generated for a different operation. Only valid with -wrapping-key-ttl.`,
+	})
+
+	f.StringVar(&StringVar{
+		Name:   "wrapping-key-audience",
+		Target: &c.flagWrappingKeyAudience,
+		Usage: `An audience the requested wrapping key will be bound to, in
+addition to the nonce. Only valid with -wrapping-key-ttl.`,
+	})
+
+	return set
 }
 
 func (c *TransitImportVersionCommand) AutocompleteArgs() complete.Predictor {
@@ -51,5 +103,22 @@ func (c *TransitImportVersionCommand) AutocompleteFlags() complete.Flags {
 }
 
 func (c *TransitImportVersionCommand) Run(args []string) int {
-	return importKey(c.BaseCommand, "import_version", c.Flags(), args)
+	f := c.Flags()
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.flagWrappingKeyTTL == 0 && (c.flagWrappingKeyNonce != "" || c.flagWrappingKeyAudience != "") {
+		c.UI.Error("-wrapping-key-nonce and -wrapping-key-audience require -wrapping-key-ttl")
+		return 1
+	}
+
+	opts := wrappingKeyOptions{
+		TTL:      c.flagWrappingKeyTTL,
+		Nonce:    c.flagWrappingKeyNonce,
+		Audience: c.flagWrappingKeyAudience,
+	}
+
+	return importKey(c.BaseCommand, "import_version", f, args, opts)
 }